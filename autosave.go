@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// defaultAutosaveDelay is how long a buffer must sit idle before its
+// pending edits are flushed to disk.
+const defaultAutosaveDelay = 500 * time.Millisecond
+
+// autosaver debounces repeated edits into a single write: every Trigger
+// call resets a timer, and save only actually runs once the buffer has
+// been idle for delay. Flush (used by the explicit Ctrl+S) writes
+// immediately and cancels any pending timer.
+type autosaver struct {
+	mu    sync.Mutex
+	timer *time.Timer
+	delay time.Duration
+	save  func()
+}
+
+func newAutosaver(delay time.Duration, save func()) *autosaver {
+	return &autosaver{delay: delay, save: save}
+}
+
+func (a *autosaver) Trigger() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.timer != nil {
+		a.timer.Stop()
+	}
+	a.timer = time.AfterFunc(a.delay, a.save)
+}
+
+func (a *autosaver) Flush() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.timer != nil {
+		a.timer.Stop()
+		a.timer = nil
+	}
+	a.save()
+}
+
+// atomicWriteFile writes data to a temp file beside path and renames it
+// into place, so a crash mid-write never leaves path truncated. On Unix it
+// also fsyncs the containing directory, so the rename itself survives a
+// crash.
+func atomicWriteFile(path string, data []byte, perm os.FileMode) error {
+	tmp := path + ".focus-tmp"
+	if err := os.WriteFile(tmp, data, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	dir, err := os.Open(filepath.Dir(path))
+	if err != nil {
+		return nil
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
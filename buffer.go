@@ -0,0 +1,227 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+)
+
+// Buffer owns one open file's text and editing state. It is independent of
+// where (or whether) it is currently visible in the pane layout, so the
+// same file can be shown in more than one pane and switching tabs never
+// has to reload content from disk.
+type Buffer struct {
+	Path    string
+	Editor  *CustomEditor
+	Dirty   bool
+	TabItem *container.TabItem
+
+	// views lists every CustomEditor currently showing this buffer - the
+	// primary Editor plus one per split pane that opened a second view
+	// onto it. An edit in any of them is mirrored into the rest via
+	// syncViews, so splitting a buffer never means two panes secretly
+	// sharing (and clobbering) the same widget instance.
+	views []*CustomEditor
+
+	autosave *autosaver
+
+	undoLog         *undoLog
+	undoDebounce    *autosaver
+	lastLoggedText  string
+	history         []string
+	historyPos      int
+	suppressHistory bool
+
+	// lastDiskHash is the sha256 of the content this buffer last saw on
+	// disk, whether from opening, saving, or a prior external-change
+	// prompt. The file watcher compares against it to tell a real
+	// external edit from the echo of our own autosave.
+	lastDiskHash string
+}
+
+// pushHistory records text as a new undo step, discarding any redo states
+// past the current position. The on-disk undo log is debounced the same
+// way autosave is: persisting a delta on every keystroke would mean one
+// synchronous disk write per character, so logging only happens once the
+// buffer goes idle (or on an explicit flush, e.g. when the window closes).
+func (b *Buffer) pushHistory(text string) {
+	b.history = append(b.history[:b.historyPos+1], text)
+	b.historyPos = len(b.history) - 1
+	if b.undoDebounce != nil {
+		b.undoDebounce.Trigger()
+	}
+}
+
+// flushUndo writes a delta for whatever's changed since lastLoggedText to
+// the on-disk undo log, if anything has.
+func (b *Buffer) flushUndo() {
+	if b.undoLog == nil {
+		return
+	}
+	text := b.Editor.Text
+	if text == b.lastLoggedText {
+		return
+	}
+	if err := b.undoLog.Append(b.lastLoggedText, text); err == nil {
+		b.lastLoggedText = text
+	}
+}
+
+// Undo restores the previous entry in this buffer's history, which may
+// have been recorded in an earlier session. b.Editor's own OnChanged mirrors
+// the restored text into any other split views of this buffer.
+func (b *Buffer) Undo() {
+	if b.historyPos == 0 {
+		return
+	}
+	b.historyPos--
+	b.suppressHistory = true
+	b.Editor.SetText(b.history[b.historyPos])
+	b.suppressHistory = false
+}
+
+// syncViews mirrors text into every other live view of b besides from, so
+// typing in one split pane shows up in a sibling pane on the same buffer.
+// OnChanged is detached for the duration of the SetText: the edit was
+// already recorded once, by from's own OnChanged, and re-running that
+// bookkeeping for every other view would double-count history/autosave/LSP
+// notifications per keystroke.
+func (b *Buffer) syncViews(from *CustomEditor, text string) {
+	for _, v := range b.views {
+		if v == from || v.Text == text {
+			continue
+		}
+		onChanged := v.OnChanged
+		v.OnChanged = nil
+		v.SetText(text)
+		v.OnChanged = onChanged
+	}
+}
+
+// wireEditor attaches editor's change/completion callbacks and registers it
+// as one of buf's live views, so edits made in any split showing buf stay
+// in sync with the others (and with the autosave/undo/LSP bookkeeping that
+// only needs to happen once per actual content change, driven by whichever
+// view the user actually typed into).
+func (f *Focus) wireEditor(buf *Buffer, editor *CustomEditor) {
+	editor.SetSyntax(filepath.Ext(buf.Path))
+	editor.OnChanged = func(text string) {
+		editor.NotifyChanged()
+		buf.Dirty = true
+		f.updateTitle()
+		if !buf.suppressHistory {
+			buf.pushHistory(text)
+		}
+		buf.autosave.Trigger()
+		if f.lsp != nil {
+			f.lsp.OnChange(buf.Path, text, editor.CursorRow, editor.CursorColumn)
+		}
+		buf.syncViews(editor, text)
+	}
+	editor.onTypedRune = func(r rune) {
+		if f.lsp != nil && !strings.ContainsRune(" \t\n", r) {
+			f.lsp.ShowCompletionPopup(f.window.Canvas(), editor, buf.Path)
+		}
+	}
+	buf.views = append(buf.views, editor)
+}
+
+// newBufferView creates an additional CustomEditor onto buf's content, for
+// showing the same buffer in more than one split pane at once. Edits in
+// either view are mirrored to the other via syncViews.
+func (f *Focus) newBufferView(buf *Buffer) *CustomEditor {
+	editor := NewCustomEditor()
+	f.wireEditor(buf, editor)
+	buf.suppressHistory = true
+	editor.SetText(buf.Editor.Text)
+	buf.suppressHistory = false
+	return editor
+}
+
+// newBuffer reads path, builds its CustomEditor, and wires that editor's
+// callbacks (debounced autosave, undo history, LSP sync, completion)
+// against this buffer's path rather than the window title, which used to
+// stand in for "current file" and broke as soon as more than one file
+// could be open.
+func (f *Focus) newBuffer(path string) (*Buffer, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	editor := NewCustomEditor()
+	buf := &Buffer{Path: path, Editor: editor, lastDiskHash: hashContent(content)}
+	buf.autosave = newAutosaver(f.autosaveDelay, func() { f.saveContent(buf) })
+
+	log, err := newUndoLog(path)
+	if err == nil {
+		buf.undoLog = log
+	}
+	buf.history = loadHistory(buf.undoLog, string(content))
+	buf.historyPos = len(buf.history) - 1
+	buf.lastLoggedText = buf.history[buf.historyPos]
+	buf.undoDebounce = newAutosaver(f.autosaveDelay, buf.flushUndo)
+
+	f.wireEditor(buf, editor)
+
+	buf.suppressHistory = true
+	editor.SetText(string(content))
+	buf.suppressHistory = false
+	buf.Dirty = false
+
+	if f.lsp != nil {
+		f.lsp.OnOpen(path, string(content))
+	}
+
+	f.checkCrashRecovery(buf)
+
+	return buf, nil
+}
+
+// loadHistory seeds a buffer's undo history from its on-disk log (if any),
+// appending the file's current on-disk content as the latest entry unless
+// the log already ends with it. Content that differs from a non-empty log's
+// last entry is also appended to the log, so the next delta is computed
+// against the log's own last entry.
+func loadHistory(log *undoLog, content string) []string {
+	var history []string
+	if log != nil {
+		if entries, err := log.Entries(); err == nil {
+			history = append(history, entries...)
+		}
+	}
+	if len(history) == 0 || history[len(history)-1] != content {
+		if log != nil && len(history) > 0 {
+			log.Append(history[len(history)-1], content)
+		}
+		history = append(history, content)
+	}
+	return history
+}
+
+// checkCrashRecovery looks for a leftover <path>.focus-tmp from a previous
+// crash (saveContent writes here before renaming into place) and offers to
+// load it instead of what's already on disk.
+func (f *Focus) checkCrashRecovery(buf *Buffer) {
+	tmpPath := buf.Path + ".focus-tmp"
+	tmpData, err := os.ReadFile(tmpPath)
+	if err != nil {
+		return
+	}
+
+	dialog.ShowConfirm(
+		"Recover unsaved changes?",
+		fmt.Sprintf("Found a leftover autosave for %s, likely from a crash. Load it instead of the file on disk?", filepath.Base(buf.Path)),
+		func(recover bool) {
+			if recover {
+				buf.Editor.SetText(string(tmpData))
+			}
+			os.Remove(tmpPath)
+		},
+		f.window,
+	)
+}
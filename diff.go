@@ -0,0 +1,108 @@
+package main
+
+// diffOp classifies one line of a myersDiff result.
+type diffOp int
+
+const (
+	diffEqual diffOp = iota
+	diffDelete
+	diffInsert
+)
+
+// diffLine is one line of an edit script: diffEqual lines appear in both
+// inputs, diffDelete only in a, diffInsert only in b.
+type diffLine struct {
+	Op   diffOp
+	Text string
+}
+
+// myersDiff computes the shortest edit script turning a into b, one line at
+// a time, using Myers' O(ND) diff algorithm. It's used by the conflict
+// diff view to show what changed between the on-disk file and the buffer.
+func myersDiff(a, b []string) []diffLine {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil
+	}
+
+	offset := max
+	size := 2*max + 1
+	v := make([]int, size)
+	var trace [][]int
+
+	found := false
+	dFound := 0
+
+outer:
+	for d := 0; d <= max; d++ {
+		snapshot := make([]int, size)
+		copy(snapshot, v)
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				dFound = d
+				found = true
+				break outer
+			}
+		}
+	}
+	if !found {
+		lines := make([]diffLine, n)
+		for i, line := range a {
+			lines[i] = diffLine{Op: diffEqual, Text: line}
+		}
+		return lines
+	}
+
+	var ops []diffLine
+	x, y := n, m
+	for d := dFound; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			ops = append(ops, diffLine{Op: diffEqual, Text: a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			ops = append(ops, diffLine{Op: diffInsert, Text: b[y-1]})
+		} else {
+			ops = append(ops, diffLine{Op: diffDelete, Text: a[x-1]})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		ops = append(ops, diffLine{Op: diffEqual, Text: a[x-1]})
+		x--
+		y--
+	}
+
+	for l, r := 0, len(ops)-1; l < r; l, r = l+1, r-1 {
+		ops[l], ops[r] = ops[r], ops[l]
+	}
+	return ops
+}
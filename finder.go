@@ -0,0 +1,83 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// allFiles flattens f.files into the plain list of open-able file paths.
+// Every directory walked by updateFiles gets its own key in f.files (even
+// when empty), so anything that isn't a key there is a file.
+func (f *Focus) allFiles() []string {
+	var out []string
+	for _, children := range f.files {
+		for _, path := range children {
+			if _, isDir := f.files[path]; !isDir {
+				out = append(out, path)
+			}
+		}
+	}
+	return out
+}
+
+// displayPath shows path relative to the open project root when possible,
+// falling back to the absolute path for anything outside it.
+func displayPath(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return rel
+}
+
+// ShowFileFinder opens the Ctrl+P fuzzy file palette: a query Entry above a
+// List of matches against allFiles(), refiltered by fuzzyMatches on every
+// keystroke.
+func (f *Focus) ShowFileFinder() {
+	candidates := f.allFiles()
+	matches := fuzzyMatches("", candidates)
+
+	list := widget.NewList(
+		func() int { return len(matches) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			obj.(*widget.Label).SetText(displayPath(f.currentDir, matches[id].Text))
+		},
+	)
+
+	var popup *widget.PopUp
+	open := func(id widget.ListItemID) {
+		if id < 0 || id >= len(matches) {
+			return
+		}
+		path := matches[id].Text
+		if popup != nil {
+			popup.Hide()
+		}
+		f.openFile(path)
+	}
+	list.OnSelected = func(id widget.ListItemID) { open(id) }
+
+	query := widget.NewEntry()
+	query.SetPlaceHolder("Go to file…")
+	query.OnChanged = func(text string) {
+		matches = fuzzyMatches(text, candidates)
+		list.Refresh()
+	}
+	query.OnSubmitted = func(string) {
+		if len(matches) > 0 {
+			open(0)
+		}
+	}
+
+	content := container.NewBorder(query, nil, nil, nil, list)
+
+	popup = widget.NewPopUp(content, f.window.Canvas())
+	popup.Resize(fyne.NewSize(480, 360))
+	popup.Show()
+	f.window.Canvas().Focus(query)
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// fuzzyMatch is one scored candidate from a fuzzy query, plus the candidate
+// rune indices that matched so callers can highlight them.
+type fuzzyMatch struct {
+	Text    string
+	Score   int
+	Indices []int
+}
+
+const (
+	fuzzyMatchBonus       = 16
+	fuzzyConsecutiveBonus = 12
+	fuzzyBoundaryBonus    = 10
+	fuzzyGapPenalty       = 2
+)
+
+// fuzzyScore rates how well pattern matches candidate as a subsequence,
+// using a Smith-Waterman-style local alignment: consecutive matches and
+// matches right after a path separator or a camelCase boundary score extra,
+// while the distance skipped between two matches costs a gap penalty. ok is
+// false if pattern isn't a subsequence of candidate at all.
+func fuzzyScore(pattern, candidate string) (score int, indices []int, ok bool) {
+	p := []rune(strings.ToLower(pattern))
+	c := []rune(candidate)
+	cl := []rune(strings.ToLower(candidate))
+
+	if len(p) == 0 {
+		return 0, nil, true
+	}
+	if len(c) < len(p) {
+		return 0, nil, false
+	}
+
+	rows, cols := len(p)+1, len(c)+1
+	dp := make([][]int, rows)
+	via := make([][]bool, rows)   // true if dp[i][j] ends in a match of p[i-1] at c[j-1]
+	fromK := make([][]int, rows) // previous row's column the match transition came from
+	for i := range dp {
+		dp[i] = make([]int, cols)
+		via[i] = make([]bool, cols)
+		fromK[i] = make([]int, cols)
+	}
+
+	matched := false // whether row i has matched p[i-1] anywhere yet
+	for i := 1; i < rows; i++ {
+		runningBest := 0
+		runningBestK := 0
+		haveRunningBest := false
+		matched = false
+		for j := 1; j < cols; j++ {
+			// consider the previous row's column j-1 as the alignment point
+			// for this match, but only where p[i-2] actually matched (row 0
+			// has no previous match, so every column qualifies), tracking the
+			// best (score + gap*k) seen so far so the match transition below
+			// stays O(1) per cell.
+			if i == 1 || via[i-1][j-1] {
+				candidateVal := dp[i-1][j-1] + fuzzyGapPenalty*(j-1)
+				if !haveRunningBest || candidateVal > runningBest {
+					runningBest = candidateVal
+					runningBestK = j - 1
+					haveRunningBest = true
+				}
+			}
+
+			skip := dp[i][j-1]
+
+			best := skip
+			bestVia := false
+			bestFromK := 0
+
+			if haveRunningBest && p[i-1] == cl[j-1] {
+				matchVal := runningBest - fuzzyGapPenalty*j + fuzzyMatchBonus
+				if isFuzzyBoundary(c, j-2) {
+					matchVal += fuzzyBoundaryBonus
+				}
+				if runningBestK == j-1 {
+					matchVal += fuzzyConsecutiveBonus
+				}
+				if !matched || matchVal > best {
+					best = matchVal
+					bestVia = true
+					bestFromK = runningBestK
+					matched = true
+				}
+			}
+
+			dp[i][j] = best
+			via[i][j] = bestVia
+			fromK[i][j] = bestFromK
+		}
+	}
+
+	if !matched {
+		return 0, nil, false
+	}
+	score = dp[rows-1][cols-1]
+
+	i, j := rows-1, cols-1
+	for i > 0 && j > 0 {
+		if via[i][j] {
+			indices = append(indices, j-1)
+			j = fromK[i][j]
+			i--
+		} else {
+			j--
+		}
+	}
+	for l, r := 0, len(indices)-1; l < r; l, r = l+1, r-1 {
+		indices[l], indices[r] = indices[r], indices[l]
+	}
+
+	return score, indices, true
+}
+
+// isFuzzyBoundary reports whether the character matched right after
+// candidate[prevIdx] starts a "word": the beginning of the string, right
+// after a path separator or punctuation, or a lower-to-upper camelCase step.
+func isFuzzyBoundary(c []rune, prevIdx int) bool {
+	if prevIdx < 0 {
+		return true
+	}
+	prev := c[prevIdx]
+	switch prev {
+	case '/', '\\', '_', '-', '.', ' ':
+		return true
+	}
+	if prevIdx+1 >= len(c) {
+		return false
+	}
+	return unicode.IsLower(prev) && unicode.IsUpper(c[prevIdx+1])
+}
+
+// fuzzyMatches scores every candidate against query and returns the matches
+// in descending score order. An empty query matches everything in its
+// original order, so the palette shows the full file list before the user
+// types anything.
+func fuzzyMatches(query string, candidates []string) []fuzzyMatch {
+	matches := make([]fuzzyMatch, 0, len(candidates))
+
+	if query == "" {
+		for _, candidate := range candidates {
+			matches = append(matches, fuzzyMatch{Text: candidate})
+		}
+		return matches
+	}
+
+	for _, candidate := range candidates {
+		score, indices, ok := fuzzyScore(query, candidate)
+		if !ok {
+			continue
+		}
+		matches = append(matches, fuzzyMatch{Text: candidate, Score: score, Indices: indices})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+
+	return matches
+}
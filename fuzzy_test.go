@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+// TestFuzzyScoreNoPanic sweeps a range of queries against realistic
+// multi-segment project paths. fuzzyScore runs on every keystroke in the
+// Ctrl+P finder, so a panic here would take down the whole app.
+func TestFuzzyScoreNoPanic(t *testing.T) {
+	candidates := []string{
+		"foo/bar/baz.go",
+		"main.go",
+		"internal/lsp/client.go",
+		"a/b/c/d/e/f/g.go",
+		"syntax/yaml.yaml",
+		"cmd/focus/main.go",
+	}
+
+	queries := []string{
+		"mg", "fbb", "main", "lsp", "abcdefg", "zzz", "", "g", "FBZ", "focus/main",
+	}
+
+	for _, candidate := range candidates {
+		for _, query := range queries {
+			fuzzyScore(query, candidate)
+		}
+	}
+}
+
+func TestFuzzyScoreMatchesSubsequence(t *testing.T) {
+	score, indices, ok := fuzzyScore("mg", "main.go")
+	if !ok {
+		t.Fatalf("expected mg to match main.go as a subsequence")
+	}
+	if score <= 0 {
+		t.Errorf("expected positive score, got %d", score)
+	}
+	if len(indices) != 2 {
+		t.Errorf("expected 2 matched indices, got %v", indices)
+	}
+}
+
+func TestFuzzyScoreRejectsNonSubsequence(t *testing.T) {
+	_, _, ok := fuzzyScore("xyz123", "foo/bar/baz.go")
+	if ok {
+		t.Errorf("expected xyz123 not to match foo/bar/baz.go")
+	}
+}
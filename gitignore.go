@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// gitignore is a minimal, single-directory .gitignore matcher: enough to
+// keep the fuzzy finder and project search from wading into build output
+// and dependency directories, not a full implementation of git's pattern
+// semantics (no negation, no nested .gitignore merging).
+type gitignore struct {
+	patterns []string
+}
+
+// loadGitignore reads root's top-level .gitignore, if any. A missing file
+// just means no extra patterns beyond the built-in dotfile/node_modules
+// skip that updateFiles already applies.
+func loadGitignore(root string) *gitignore {
+	f, err := os.Open(filepath.Join(root, ".gitignore"))
+	if err != nil {
+		return &gitignore{}
+	}
+	defer f.Close()
+
+	g := &gitignore{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		g.patterns = append(g.patterns, strings.TrimSuffix(line, "/"))
+	}
+	return g
+}
+
+// Matches reports whether relPath (separated by filepath.Separator, as
+// filepath.Rel returns it, and relative to the root passed to
+// loadGitignore) should be ignored: any path component matching
+// a pattern, or the whole relative path matching one, is enough.
+func (g *gitignore) Matches(relPath string) bool {
+	for _, part := range strings.Split(relPath, string(filepath.Separator)) {
+		for _, pattern := range g.patterns {
+			if ok, _ := filepath.Match(pattern, part); ok {
+				return true
+			}
+		}
+	}
+	for _, pattern := range g.patterns {
+		if ok, _ := filepath.Match(pattern, relPath); ok {
+			return true
+		}
+	}
+	return false
+}
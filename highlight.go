@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+)
+
+// TokenType classifies a lexed span of source text for coloring purposes.
+type TokenType string
+
+const (
+	TokenDefault     TokenType = "default"
+	TokenKeyword     TokenType = "keyword"
+	TokenString      TokenType = "string"
+	TokenComment     TokenType = "comment"
+	TokenNumber      TokenType = "number"
+	TokenPunctuation TokenType = "punctuation"
+	TokenIdentifier  TokenType = "identifier"
+)
+
+// Token is a single highlighted span, expressed as a line number plus a
+// column range, so re-tokenizing one line never has to touch byte offsets
+// in the rest of the document.
+type Token struct {
+	Line     int
+	StartCol int
+	EndCol   int
+	Type     TokenType
+}
+
+// Highlighter turns source bytes into a flat list of tokens. Implementations
+// are free to tokenize the whole buffer or just the lines that changed.
+type Highlighter interface {
+	Tokenize(src []byte) []Token
+}
+
+// highlighterFor picks the Highlighter for a file extension, falling back to
+// the TextMate-style grammar loader for anything without a hand-written lexer.
+func highlighterFor(ext string) Highlighter {
+	switch ext {
+	case ".go":
+		return GoLexer{}
+	case ".json":
+		return JSONLexer{}
+	case ".xml", ".html", ".htm":
+		return XMLLexer{}
+	default:
+		if grammar, ok := loadedGrammars[ext]; ok {
+			return grammar
+		}
+		return nil
+	}
+}
+
+// Theme maps token types to display colors, loaded from a JSON file so users
+// can swap palettes without recompiling.
+type Theme struct {
+	Colors map[TokenType]color.Color
+}
+
+type themeFile struct {
+	Colors map[string]string `json:"colors"`
+}
+
+// DefaultTheme mirrors the colors the old regex-based highlighter used.
+func DefaultTheme() *Theme {
+	return &Theme{
+		Colors: map[TokenType]color.Color{
+			TokenKeyword:     mustParseHexColor("#00ADD8"),
+			TokenString:      mustParseHexColor("#FFA500"),
+			TokenComment:     mustParseHexColor("#98C379"),
+			TokenNumber:      mustParseHexColor("#D19A66"),
+			TokenPunctuation: mustParseHexColor("#ABB2BF"),
+			TokenIdentifier:  mustParseHexColor("#DCDFE4"),
+			TokenDefault:     color.White,
+		},
+	}
+}
+
+// activeTheme is populated at startup by loadThemeFile and handed to every
+// new CustomEditor, so swapping the theme file changes colors without a
+// recompile.
+var activeTheme *Theme
+
+// currentTheme returns the theme loaded at startup, falling back to
+// DefaultTheme if no theme file was found or it failed to parse.
+func currentTheme() *Theme {
+	if activeTheme != nil {
+		return activeTheme
+	}
+	return DefaultTheme()
+}
+
+// loadThemeFile loads path into activeTheme, leaving DefaultTheme in effect
+// if the file is missing or invalid.
+func loadThemeFile(path string) {
+	theme, err := LoadTheme(path)
+	if err != nil {
+		return
+	}
+	activeTheme = theme
+}
+
+// themeConfigPath returns the user's configured theme file,
+// ~/.config/focus/theme.json, falling back to the theme shipped alongside
+// the binary if the user hasn't customized one.
+func themeConfigPath() string {
+	if home, err := os.UserHomeDir(); err == nil {
+		path := filepath.Join(home, ".config", "focus", "theme.json")
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return filepath.Join("themes", "default.json")
+}
+
+// LoadTheme reads a theme JSON file of the form {"colors": {"keyword": "#00ADD8", ...}}.
+func LoadTheme(path string) (*Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var tf themeFile
+	if err := json.Unmarshal(data, &tf); err != nil {
+		return nil, err
+	}
+
+	theme := DefaultTheme()
+	for name, hex := range tf.Colors {
+		c, err := parseHexColor(hex)
+		if err != nil {
+			continue
+		}
+		theme.Colors[TokenType(name)] = c
+	}
+	return theme, nil
+}
+
+func (t *Theme) Color(tt TokenType) color.Color {
+	if c, ok := t.Colors[tt]; ok {
+		return c
+	}
+	return t.Colors[TokenDefault]
+}
+
+// DiagnosticColor returns the underline/text color for an LSP diagnostic
+// severity (1=Error, 2=Warning, 3=Information, 4=Hint), falling back to the
+// same color as Information for anything else a server might send.
+func (t *Theme) DiagnosticColor(severity int) color.Color {
+	switch severity {
+	case 1:
+		return mustParseHexColor("#E06C75")
+	case 2:
+		return mustParseHexColor("#E5C07B")
+	default:
+		return mustParseHexColor("#61AFEF")
+	}
+}
+
+func parseHexColor(hex string) (color.Color, error) {
+	var r, g, b uint8
+	if _, err := fmt.Sscanf(hex, "#%02x%02x%02x", &r, &g, &b); err != nil {
+		return nil, err
+	}
+	return color.NRGBA{R: r, G: g, B: b, A: 0xff}, nil
+}
+
+func mustParseHexColor(hex string) color.Color {
+	c, err := parseHexColor(hex)
+	if err != nil {
+		return color.White
+	}
+	return c
+}
+
+// dirtyLineSet tracks which lines need re-lexing after an edit, so
+// incremental re-tokenization only has to touch the lines the user changed.
+type dirtyLineSet struct {
+	lines map[int]bool
+}
+
+func newDirtyLineSet() *dirtyLineSet {
+	return &dirtyLineSet{lines: make(map[int]bool)}
+}
+
+func (d *dirtyLineSet) Mark(line int) {
+	d.lines[line] = true
+}
+
+func (d *dirtyLineSet) MarkRange(from, to int) {
+	for line := from; line <= to; line++ {
+		d.Mark(line)
+	}
+}
+
+func (d *dirtyLineSet) Clear() {
+	d.lines = make(map[int]bool)
+}
+
+func (d *dirtyLineSet) IsDirty(line int) bool {
+	return d.lines[line]
+}
+
+// loadedGrammars is populated at startup by loadSyntaxDir.
+var loadedGrammars = map[string]*Grammar{}
+
+func loadSyntaxDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		grammar, err := loadGrammar(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		loadedGrammars[grammar.Extension] = grammar
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// layoutNode is the JSON-serializable mirror of PaneTree, used to persist
+// split offsets (and which file each pane shows) across sessions.
+type layoutNode struct {
+	Path       string      `json:"path,omitempty"`
+	Horizontal bool        `json:"horizontal,omitempty"`
+	Offset     float64     `json:"offset,omitempty"`
+	A          *layoutNode `json:"a,omitempty"`
+	B          *layoutNode `json:"b,omitempty"`
+}
+
+func layoutPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "focus", "layout.json"), nil
+}
+
+func toLayoutNode(t *PaneTree) *layoutNode {
+	if t.Pane != nil {
+		return &layoutNode{Path: t.Pane.Buffer.Path}
+	}
+	return &layoutNode{
+		Horizontal: t.Split.Horizontal,
+		Offset:     t.Split.Offset,
+		A:          toLayoutNode(t.Split.A),
+		B:          toLayoutNode(t.Split.B),
+	}
+}
+
+// SaveLayout writes the current split layout (offsets and which file each
+// pane shows) so it can be restored on the next launch.
+func (f *Focus) SaveLayout() error {
+	if f.paneTree == nil {
+		return nil
+	}
+	f.paneTree.syncOffset()
+
+	path, err := layoutPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(toLayoutNode(f.paneTree), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// fromLayoutNode rebuilds a PaneTree from its saved form. seen tracks which
+// buffers have already been handed out a pane in this restore: the first
+// pane showing a given buffer gets its primary Editor, but a buffer that
+// was split before saving appears twice in the layout, and the second
+// occurrence needs its own CustomEditor (see Focus.newBufferView) rather
+// than sharing the first pane's widget instance.
+func fromLayoutNode(f *Focus, n *layoutNode, resolve func(path string) (*Buffer, error), seen map[*Buffer]bool) (*PaneTree, error) {
+	if n.A == nil && n.B == nil {
+		buf, err := resolve(n.Path)
+		if err != nil {
+			return nil, err
+		}
+		editor := buf.Editor
+		if seen[buf] {
+			editor = f.newBufferView(buf)
+		}
+		seen[buf] = true
+		return &PaneTree{Pane: &Pane{Buffer: buf, Editor: editor}}, nil
+	}
+
+	a, err := fromLayoutNode(f, n.A, resolve, seen)
+	if err != nil {
+		return nil, err
+	}
+	b, err := fromLayoutNode(f, n.B, resolve, seen)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaneTree{Split: &paneSplit{
+		Horizontal: n.Horizontal,
+		Offset:     n.Offset,
+		A:          a,
+		B:          b,
+	}}, nil
+}
+
+// LoadLayout restores a previously saved split layout. resolve is used to
+// get (or open) the Buffer for each pane's recorded path.
+func (f *Focus) LoadLayout(resolve func(path string) (*Buffer, error)) (*PaneTree, error) {
+	path, err := layoutPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var node layoutNode
+	if err := json.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+
+	return fromLayoutNode(f, &node, resolve, make(map[*Buffer]bool))
+}
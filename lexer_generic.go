@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Grammar is a TextMate-style regex grammar loaded from a syntax/*.yaml
+// file, modeled after how micro ships its own YAML syntax definitions. It
+// is the fallback Highlighter for any extension without a hand-written lexer.
+type Grammar struct {
+	Extension string
+	Rules     []grammarRule
+}
+
+type grammarRule struct {
+	Pattern *regexp.Regexp
+	Type    TokenType
+}
+
+type grammarFile struct {
+	Extension string `yaml:"extension"`
+	Rules     []struct {
+		Pattern string `yaml:"pattern"`
+		Token   string `yaml:"token"`
+	} `yaml:"rules"`
+}
+
+func loadGrammar(path string) (*Grammar, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var gf grammarFile
+	if err := yaml.Unmarshal(data, &gf); err != nil {
+		return nil, err
+	}
+
+	grammar := &Grammar{Extension: gf.Extension}
+	for _, rule := range gf.Rules {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			continue
+		}
+		grammar.Rules = append(grammar.Rules, grammarRule{Pattern: re, Type: TokenType(rule.Token)})
+	}
+	return grammar, nil
+}
+
+func (g *Grammar) Tokenize(src []byte) []Token {
+	var tokens []Token
+	lines := strings.Split(string(src), "\n")
+
+	for lineNum, line := range lines {
+		for _, rule := range g.Rules {
+			for _, loc := range rule.Pattern.FindAllStringIndex(line, -1) {
+				tokens = append(tokens, Token{
+					Line:     lineNum,
+					StartCol: len([]rune(line[:loc[0]])),
+					EndCol:   len([]rune(line[:loc[1]])),
+					Type:     rule.Type,
+				})
+			}
+		}
+	}
+
+	return tokens
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// GoLexer is a small hand-written scanner for Go source, fast enough to
+// re-run on every keystroke without the regex-per-color approach the old
+// applySyntaxHighlighting used.
+type GoLexer struct{}
+
+var goKeywords = map[string]bool{
+	"func": true, "package": true, "import": true, "return": true,
+	"if": true, "else": true, "for": true, "range": true, "var": true,
+	"const": true, "type": true, "struct": true, "interface": true,
+	"map": true, "chan": true, "go": true, "defer": true, "select": true,
+	"case": true, "default": true, "break": true, "continue": true,
+	"switch": true, "fallthrough": true, "goto": true, "nil": true,
+	"true": true, "false": true,
+}
+
+func (GoLexer) Tokenize(src []byte) []Token {
+	var tokens []Token
+	lines := strings.Split(string(src), "\n")
+
+	for lineNum, line := range lines {
+		runes := []rune(line)
+		i := 0
+		for i < len(runes) {
+			c := runes[i]
+
+			switch {
+			case c == '/' && i+1 < len(runes) && runes[i+1] == '/':
+				tokens = append(tokens, Token{Line: lineNum, StartCol: i, EndCol: len(runes), Type: TokenComment})
+				i = len(runes)
+
+			case c == '"' || c == '`':
+				start := i
+				quote := c
+				i++
+				for i < len(runes) && runes[i] != quote {
+					if quote == '"' && runes[i] == '\\' && i+1 < len(runes) {
+						i++
+					}
+					i++
+				}
+				if i < len(runes) {
+					i++
+				}
+				tokens = append(tokens, Token{Line: lineNum, StartCol: start, EndCol: i, Type: TokenString})
+
+			case unicode.IsDigit(c):
+				start := i
+				for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+					i++
+				}
+				tokens = append(tokens, Token{Line: lineNum, StartCol: start, EndCol: i, Type: TokenNumber})
+
+			case unicode.IsLetter(c) || c == '_':
+				start := i
+				for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+					i++
+				}
+				word := string(runes[start:i])
+				tokenType := TokenIdentifier
+				if goKeywords[word] {
+					tokenType = TokenKeyword
+				}
+				tokens = append(tokens, Token{Line: lineNum, StartCol: start, EndCol: i, Type: tokenType})
+
+			case strings.ContainsRune("{}()[],.;:=+-*/%<>!&|^~", c):
+				tokens = append(tokens, Token{Line: lineNum, StartCol: i, EndCol: i + 1, Type: TokenPunctuation})
+				i++
+
+			default:
+				i++
+			}
+		}
+	}
+
+	return tokens
+}
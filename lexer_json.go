@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// JSONLexer tokenizes JSON source for the editor's syntax highlighting.
+type JSONLexer struct{}
+
+func (JSONLexer) Tokenize(src []byte) []Token {
+	var tokens []Token
+	lines := strings.Split(string(src), "\n")
+
+	for lineNum, line := range lines {
+		runes := []rune(line)
+		i := 0
+		for i < len(runes) {
+			c := runes[i]
+
+			switch {
+			case c == '"':
+				start := i
+				i++
+				for i < len(runes) && runes[i] != '"' {
+					if runes[i] == '\\' && i+1 < len(runes) {
+						i++
+					}
+					i++
+				}
+				if i < len(runes) {
+					i++
+				}
+				tokens = append(tokens, Token{Line: lineNum, StartCol: start, EndCol: i, Type: TokenString})
+
+			case unicode.IsDigit(c) || (c == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+				start := i
+				i++
+				for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == 'e' || runes[i] == 'E' || runes[i] == '+' || runes[i] == '-') {
+					i++
+				}
+				tokens = append(tokens, Token{Line: lineNum, StartCol: start, EndCol: i, Type: TokenNumber})
+
+			case unicode.IsLetter(c):
+				start := i
+				for i < len(runes) && unicode.IsLetter(runes[i]) {
+					i++
+				}
+				word := string(runes[start:i])
+				if word == "true" || word == "false" || word == "null" {
+					tokens = append(tokens, Token{Line: lineNum, StartCol: start, EndCol: i, Type: TokenKeyword})
+				} else {
+					tokens = append(tokens, Token{Line: lineNum, StartCol: start, EndCol: i, Type: TokenIdentifier})
+				}
+
+			case strings.ContainsRune("{}[],:", c):
+				tokens = append(tokens, Token{Line: lineNum, StartCol: i, EndCol: i + 1, Type: TokenPunctuation})
+				i++
+
+			default:
+				i++
+			}
+		}
+	}
+
+	return tokens
+}
@@ -0,0 +1,72 @@
+package main
+
+import "strings"
+
+// XMLLexer tokenizes XML/HTML source. It distinguishes tags, attribute
+// strings and comments, but does not attempt a full attribute grammar.
+type XMLLexer struct{}
+
+func (XMLLexer) Tokenize(src []byte) []Token {
+	var tokens []Token
+	lines := strings.Split(string(src), "\n")
+
+	for lineNum, line := range lines {
+		runes := []rune(line)
+		i := 0
+		for i < len(runes) {
+			c := runes[i]
+
+			switch {
+			case c == '<' && i+3 < len(runes) && string(runes[i:i+4]) == "<!--":
+				start := i
+				end := strings.Index(string(runes[i:]), "-->")
+				if end == -1 {
+					i = len(runes)
+				} else {
+					i += end + 3
+				}
+				tokens = append(tokens, Token{Line: lineNum, StartCol: start, EndCol: i, Type: TokenComment})
+
+			case c == '<':
+				start := i
+				for i < len(runes) && runes[i] != '>' {
+					if runes[i] == '"' {
+						tokens = append(tokens, Token{Line: lineNum, StartCol: start, EndCol: i, Type: TokenKeyword})
+						strStart := i
+						i++
+						for i < len(runes) && runes[i] != '"' {
+							i++
+						}
+						if i < len(runes) {
+							i++
+						}
+						tokens = append(tokens, Token{Line: lineNum, StartCol: strStart, EndCol: i, Type: TokenString})
+						start = i
+						continue
+					}
+					i++
+				}
+				if i < len(runes) {
+					i++
+				}
+				tokens = append(tokens, Token{Line: lineNum, StartCol: start, EndCol: i, Type: TokenKeyword})
+
+			case c == '"':
+				start := i
+				i++
+				for i < len(runes) && runes[i] != '"' {
+					i++
+				}
+				if i < len(runes) {
+					i++
+				}
+				tokens = append(tokens, Token{Line: lineNum, StartCol: start, EndCol: i, Type: TokenString})
+
+			default:
+				i++
+			}
+		}
+	}
+
+	return tokens
+}
@@ -0,0 +1,600 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+	"gopkg.in/yaml.v3"
+)
+
+// LSPServerConfig describes how to launch a language server for a file extension.
+type LSPServerConfig struct {
+	Command     string                 `yaml:"command"`
+	Args        []string               `yaml:"args"`
+	InitOptions map[string]interface{} `yaml:"initOptions"`
+}
+
+func loadLSPConfig() (map[string]LSPServerConfig, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(home, ".config", "focus", "servers.yaml")
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]LSPServerConfig{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg map[string]LSPServerConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Diagnostic mirrors the subset of textDocument/publishDiagnostics we render:
+// its start and end position (so the gutter/underline renderer knows which
+// span to mark), severity (1=Error, 2=Warning, 3=Information, 4=Hint), and
+// message text.
+type Diagnostic struct {
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndLine   int    `json:"endLine"`
+	EndColumn int    `json:"endColumn"`
+	Severity  int    `json:"severity"`
+	Message   string `json:"message"`
+}
+
+// Position and Range mirror LSP's 0-indexed line/character coordinates.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+type CompletionItem struct {
+	Label               string     `json:"label"`
+	InsertText          string     `json:"insertText"`
+	TextEdit            *TextEdit  `json:"textEdit"`
+	AdditionalTextEdits []TextEdit `json:"additionalTextEdits"`
+}
+
+// LSPClient talks JSON-RPC to a single language server over stdio.
+type LSPClient struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	stdout  *bufio.Reader
+	mu      sync.Mutex
+	nextID  int
+	pending map[int]chan json.RawMessage
+
+	onDiagnostics func(uri string, diags []Diagnostic)
+}
+
+// NewLSPClient launches the configured server with its working directory
+// set to rootDir (the opened project root) and reports that same root to
+// the server via initialize's rootUri/workspaceFolders, so servers like
+// gopls can resolve the module the edited files belong to instead of
+// guessing from their own cwd.
+func NewLSPClient(cfg LSPServerConfig, rootDir string, onDiagnostics func(uri string, diags []Diagnostic)) (*LSPClient, error) {
+	cmd := exec.Command(cfg.Command, cfg.Args...)
+	cmd.Dir = rootDir
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	c := &LSPClient{
+		cmd:           cmd,
+		stdin:         stdin,
+		stdout:        bufio.NewReader(stdout),
+		pending:       make(map[int]chan json.RawMessage),
+		onDiagnostics: onDiagnostics,
+	}
+
+	go c.readLoop()
+
+	rootURI := fileURI(rootDir)
+	if _, err := c.request("initialize", map[string]interface{}{
+		"processId":    os.Getpid(),
+		"rootUri":      rootURI,
+		"capabilities": map[string]interface{}{},
+		"workspaceFolders": []map[string]interface{}{
+			{"uri": rootURI, "name": filepath.Base(rootDir)},
+		},
+		"initializationOptions": cfg.InitOptions,
+	}); err != nil {
+		return nil, err
+	}
+	c.notify("initialized", map[string]interface{}{})
+
+	return c, nil
+}
+
+func (c *LSPClient) readLoop() {
+	for {
+		headers := make(map[string]string)
+		for {
+			line, err := c.stdout.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			if line == "" {
+				break
+			}
+			parts := strings.SplitN(line, ":", 2)
+			if len(parts) == 2 {
+				headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+			}
+		}
+
+		length, err := strconv.Atoi(headers["Content-Length"])
+		if err != nil {
+			continue
+		}
+
+		body := make([]byte, length)
+		if _, err := io.ReadFull(c.stdout, body); err != nil {
+			return
+		}
+
+		c.handleMessage(body)
+	}
+}
+
+func (c *LSPClient) handleMessage(body []byte) {
+	var msg struct {
+		ID     *int            `json:"id"`
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return
+	}
+
+	if msg.ID != nil {
+		c.mu.Lock()
+		ch, ok := c.pending[*msg.ID]
+		delete(c.pending, *msg.ID)
+		c.mu.Unlock()
+		if ok {
+			ch <- msg.Result
+		}
+		return
+	}
+
+	if msg.Method == "textDocument/publishDiagnostics" && c.onDiagnostics != nil {
+		var params struct {
+			URI         string `json:"uri"`
+			Diagnostics []struct {
+				Range    Range  `json:"range"`
+				Severity int    `json:"severity"`
+				Message  string `json:"message"`
+			} `json:"diagnostics"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return
+		}
+
+		diags := make([]Diagnostic, 0, len(params.Diagnostics))
+		for _, d := range params.Diagnostics {
+			diags = append(diags, Diagnostic{
+				Line:      d.Range.Start.Line,
+				Column:    d.Range.Start.Character,
+				EndLine:   d.Range.End.Line,
+				EndColumn: d.Range.End.Character,
+				Severity:  d.Severity,
+				Message:   d.Message,
+			})
+		}
+		c.onDiagnostics(params.URI, diags)
+	}
+}
+
+func (c *LSPClient) write(payload map[string]interface{}) error {
+	payload["jsonrpc"] = "2.0"
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(c.stdin, "Content-Length: %d\r\n\r\n%s", len(data), data)
+	return err
+}
+
+func (c *LSPClient) notify(method string, params interface{}) error {
+	return c.write(map[string]interface{}{"method": method, "params": params})
+}
+
+func (c *LSPClient) request(method string, params interface{}) (json.RawMessage, error) {
+	c.mu.Lock()
+	id := c.nextID
+	c.nextID++
+	ch := make(chan json.RawMessage, 1)
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	if err := c.write(map[string]interface{}{"id": id, "method": method, "params": params}); err != nil {
+		return nil, err
+	}
+
+	return <-ch, nil
+}
+
+func (c *LSPClient) DidOpen(uri, languageID, text string) error {
+	return c.notify("textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{
+			"uri":        uri,
+			"languageId": languageID,
+			"version":    1,
+			"text":       text,
+		},
+	})
+}
+
+func (c *LSPClient) DidChange(uri string, rng Range, text string) error {
+	return c.notify("textDocument/didChange", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+		"contentChanges": []map[string]interface{}{
+			{"range": rng, "text": text},
+		},
+	})
+}
+
+func (c *LSPClient) DidSave(uri string) error {
+	return c.notify("textDocument/didSave", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+	})
+}
+
+func (c *LSPClient) Completion(uri string, pos Position) ([]CompletionItem, error) {
+	result, err := c.request("textDocument/completion", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+		"position":     pos,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var list struct {
+		Items []CompletionItem `json:"items"`
+	}
+	if err := json.Unmarshal(result, &list); err == nil && len(list.Items) > 0 {
+		return list.Items, nil
+	}
+
+	var items []CompletionItem
+	if err := json.Unmarshal(result, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (c *LSPClient) Formatting(uri string) ([]TextEdit, error) {
+	result, err := c.request("textDocument/formatting", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+		"options":      map[string]interface{}{"tabSize": 4, "insertSpaces": true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var edits []TextEdit
+	if err := json.Unmarshal(result, &edits); err != nil {
+		return nil, err
+	}
+	return edits, nil
+}
+
+func (c *LSPClient) Shutdown() {
+	c.request("shutdown", nil)
+	c.notify("exit", nil)
+	c.stdin.Close()
+	c.cmd.Wait()
+}
+
+func fileURI(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}).String()
+}
+
+// lspLanguageID maps a file extension to the languageId LSP servers expect.
+func lspLanguageID(ext string) string {
+	switch ext {
+	case ".go":
+		return "go"
+	case ".json":
+		return "jsonc"
+	default:
+		return strings.TrimPrefix(ext, ".")
+	}
+}
+
+// lspManager owns one LSPClient per configured extension, the last
+// diagnostics received, and the last text sent, which OnChange diffs against
+// for incremental didChange. No document version is tracked or sent.
+type lspManager struct {
+	focus   *Focus
+	config  map[string]LSPServerConfig
+	clients map[string]*LSPClient
+
+	currentURI  string
+	lastText    string
+	diagnostics []Diagnostic
+}
+
+func newLSPManager(f *Focus) *lspManager {
+	cfg, err := loadLSPConfig()
+	if err != nil {
+		cfg = map[string]LSPServerConfig{}
+	}
+	return &lspManager{
+		focus:   f,
+		config:  cfg,
+		clients: make(map[string]*LSPClient),
+	}
+}
+
+func (m *lspManager) clientFor(ext string) (*LSPClient, bool) {
+	if client, ok := m.clients[ext]; ok {
+		return client, true
+	}
+
+	cfg, ok := m.config[ext]
+	if !ok {
+		return nil, false
+	}
+
+	client, err := NewLSPClient(cfg, m.focus.currentDir, m.handleDiagnostics)
+	if err != nil {
+		return nil, false
+	}
+	m.clients[ext] = client
+	return client, true
+}
+
+// handleDiagnostics is called from LSPClient.readLoop's goroutine, so every
+// widget touch here has to go through fyne.Do rather than mutate the UI
+// off the main thread.
+func (m *lspManager) handleDiagnostics(uri string, diags []Diagnostic) {
+	if uri != m.currentURI {
+		return
+	}
+	m.diagnostics = diags
+	fyne.Do(func() {
+		if m.focus.editor != nil {
+			m.focus.editor.SetDiagnostics(diags)
+		}
+		if m.focus.diagPanel != nil {
+			lines := make([]string, 0, len(diags))
+			for _, d := range diags {
+				lines = append(lines, fmt.Sprintf("line %d: %s", d.Line+1, d.Message))
+			}
+			m.focus.diagPanel.SetText(strings.Join(lines, "\n"))
+		}
+	})
+}
+
+func (m *lspManager) OnOpen(path, text string) {
+	ext := filepath.Ext(path)
+	client, ok := m.clientFor(ext)
+	if !ok {
+		return
+	}
+
+	m.currentURI = fileURI(path)
+	m.lastText = text
+	client.DidOpen(m.currentURI, lspLanguageID(ext), text)
+}
+
+// OnChange diffs the previous buffer against the new one to find the
+// smallest changed line range. The range comes from that line diff alone;
+// cursorRow and cursorColumn are not used.
+func (m *lspManager) OnChange(path, text string, cursorRow, cursorColumn int) {
+	ext := filepath.Ext(path)
+	client, ok := m.clientFor(ext)
+	if !ok {
+		m.lastText = text
+		return
+	}
+
+	oldLines := strings.Split(m.lastText, "\n")
+	newLines := strings.Split(text, "\n")
+
+	startLine := 0
+	for startLine < len(oldLines) && startLine < len(newLines) && oldLines[startLine] == newLines[startLine] {
+		startLine++
+	}
+
+	oldEnd := len(oldLines)
+	newEnd := len(newLines)
+	for oldEnd > startLine && newEnd > startLine && oldLines[oldEnd-1] == newLines[newEnd-1] {
+		oldEnd--
+		newEnd--
+	}
+
+	rng := Range{
+		Start: Position{Line: startLine, Character: 0},
+		End:   Position{Line: oldEnd, Character: 0},
+	}
+	if oldEnd > len(oldLines)-1 {
+		rng.End = Position{Line: len(oldLines) - 1, Character: len(lastOf(oldLines))}
+	}
+
+	replacement := strings.Join(newLines[startLine:newEnd], "\n")
+	if newEnd < len(newLines) {
+		replacement += "\n"
+	}
+
+	m.lastText = text
+	client.DidChange(m.currentURI, rng, replacement)
+}
+
+func lastOf(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return lines[len(lines)-1]
+}
+
+func (m *lspManager) OnSave(path string) {
+	client, ok := m.clientFor(filepath.Ext(path))
+	if !ok {
+		return
+	}
+	client.DidSave(m.currentURI)
+}
+
+// ShowCompletionPopup requests completions for editor's current cursor
+// position and shows them in a popup list. The request runs on its own
+// goroutine, the same as diagnostics, since it blocks on the language
+// server's JSON-RPC reply - doing that synchronously on every keystroke
+// (this is called from onTypedRune, on the UI goroutine) would freeze the
+// whole editor until the server answered.
+func (m *lspManager) ShowCompletionPopup(canvas fyne.Canvas, editor *CustomEditor, path string) {
+	client, ok := m.clientFor(filepath.Ext(path))
+	if !ok {
+		return
+	}
+
+	uri := m.currentURI
+	pos := Position{Line: editor.CursorRow, Character: editor.CursorColumn}
+
+	go func() {
+		items, err := client.Completion(uri, pos)
+		if err != nil || len(items) == 0 {
+			return
+		}
+
+		fyne.Do(func() {
+			list := widget.NewList(
+				func() int { return len(items) },
+				func() fyne.CanvasObject { return widget.NewLabel("") },
+				func(id widget.ListItemID, obj fyne.CanvasObject) {
+					obj.(*widget.Label).SetText(items[id].Label)
+				},
+			)
+
+			var popup *widget.PopUp
+			list.OnSelected = func(id widget.ListItemID) {
+				applyCompletionItem(editor, items[id])
+				if popup != nil {
+					popup.Hide()
+				}
+			}
+
+			popup = widget.NewPopUp(list, canvas)
+			popup.Resize(fyne.NewSize(300, 200))
+			popup.ShowAtPosition(editor.Position())
+		})
+	}()
+}
+
+// applyCompletionItem applies item's primary edit, then its
+// AdditionalTextEdits in reverse document order (last edit first), the same
+// order Format uses for multi-edit responses: applying forward would mean
+// each edit's range is computed against text that an earlier edit in the
+// same batch already shifted.
+func applyCompletionItem(editor *CustomEditor, item CompletionItem) {
+	edit := item.TextEdit
+	if edit != nil {
+		applyTextEdit(editor, *edit)
+	} else {
+		cursor := Position{Line: editor.CursorRow, Character: editor.CursorColumn}
+		applyTextEdit(editor, TextEdit{Range: Range{Start: cursor, End: cursor}, NewText: item.InsertText})
+	}
+
+	additional := item.AdditionalTextEdits
+	for i := len(additional) - 1; i >= 0; i-- {
+		applyTextEdit(editor, additional[i])
+	}
+}
+
+// applyTextEdit replaces the text spanned by edit.Range with edit.NewText,
+// converting LSP's line/character coordinates to byte offsets in the buffer.
+func applyTextEdit(editor *CustomEditor, edit TextEdit) {
+	lines := strings.Split(editor.Text, "\n")
+	startOffset := offsetForPosition(lines, edit.Range.Start)
+	endOffset := offsetForPosition(lines, edit.Range.End)
+
+	newText := editor.Text[:startOffset] + edit.NewText + editor.Text[endOffset:]
+	editor.SetText(newText)
+}
+
+func offsetForPosition(lines []string, pos Position) int {
+	offset := 0
+	for i := 0; i < pos.Line && i < len(lines); i++ {
+		offset += len(lines[i]) + 1
+	}
+	if pos.Line < len(lines) {
+		offset += min(pos.Character, len(lines[pos.Line]))
+	}
+	return offset
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func (m *lspManager) Format(path string) {
+	client, ok := m.clientFor(filepath.Ext(path))
+	if !ok {
+		return
+	}
+
+	edits, err := client.Formatting(m.currentURI)
+	if err != nil {
+		return
+	}
+
+	editor := m.focus.editor
+	for i := len(edits) - 1; i >= 0; i-- {
+		applyTextEdit(editor, edits[i])
+	}
+}
+
+func (m *lspManager) Shutdown() {
+	for _, client := range m.clients {
+		client.Shutdown()
+	}
+}
@@ -1,18 +1,19 @@
 package main
 
 import (
-	"fmt"
+	"image/color"
 	"log"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 )
 
@@ -23,17 +24,69 @@ type Focus struct {
 	currentDir string
 	files      map[string][]string
 	fontSize   float32
+	lsp        *lspManager
+	diagPanel  *widget.Label
+
+	buffers       []*Buffer
+	tabs          *container.DocTabs
+	paneTree      *PaneTree
+	activePane    *Pane
+	paneArea      *fyne.Container
+	autosaveDelay time.Duration
+	watcher       *fileWatcher
 }
 
 type CustomEditor struct {
 	widget.Entry
-	fontSize float32
-	syntax   string
+	fontSize      float32
+	syntax        string
+	diagnostics   []Diagnostic
+	onTypedRune   func(r rune)
+	onFocusGained func()
+
+	highlighter Highlighter
+	theme       *Theme
+	lineTokens  map[int][]Token
+	lineCount   int
+	dirty       *dirtyLineSet
+	rich        *widget.RichText
+	gutter      *widget.RichText
+}
+
+func (e *CustomEditor) TypedRune(r rune) {
+	e.Entry.TypedRune(r)
+	if e.onTypedRune != nil {
+		e.onTypedRune(r)
+	}
+}
+
+// FocusGained lets the owning pane notice it's the one the user clicked or
+// tabbed into, so Ctrl+S/Ctrl+Z/format and the split shortcuts act on the
+// pane actually being looked at instead of whichever was last split.
+func (e *CustomEditor) FocusGained() {
+	e.Entry.FocusGained()
+	if e.onFocusGained != nil {
+		e.onFocusGained()
+	}
+}
+
+// SetDiagnostics stores the diagnostics most recently published by the
+// language server for the file currently open in this editor, and redraws
+// the gutter markers and underlines that reflect them.
+func (e *CustomEditor) SetDiagnostics(diags []Diagnostic) {
+	e.diagnostics = diags
+	if e.rich != nil {
+		e.rebuildRichText()
+	}
+	e.Refresh()
 }
 
 func NewCustomEditor() *CustomEditor {
 	editor := &CustomEditor{
-		fontSize: 12,
+		fontSize:   12,
+		theme:      currentTheme(),
+		lineTokens: make(map[int][]Token),
+		dirty:      newDirtyLineSet(),
 	}
 	editor.ExtendBaseWidget(editor)
 	editor.MultiLine = true
@@ -53,53 +106,458 @@ func (e *CustomEditor) MinSize() fyne.Size {
 	return e.Entry.MinSize()
 }
 
+// SetSyntax switches the Highlighter used for the open file and forces a
+// full re-tokenization, since the old token cache no longer applies.
+func (e *CustomEditor) SetSyntax(ext string) {
+	e.syntax = ext
+	e.highlighter = highlighterFor(ext)
+	e.lineTokens = make(map[int][]Token)
+	e.lineCount = strings.Count(e.Text, "\n") + 1
+	e.dirty.Clear()
+	if e.rich != nil {
+		e.rebuildRichText()
+	}
+}
+
+// SetText overrides widget.Entry.SetText to additionally force a full
+// re-tokenization, since the whole buffer just changed out from under us.
+func (e *CustomEditor) SetText(text string) {
+	e.Entry.SetText(text)
+	e.lineTokens = make(map[int][]Token)
+	e.lineCount = strings.Count(e.Text, "\n") + 1
+	e.dirty.Clear()
+	if e.rich != nil {
+		e.rebuildRichText()
+	}
+}
+
+// NotifyChanged re-lexes the lines around the cursor and refreshes the
+// RichText overlay. It's called after every edit instead of re-tokenizing
+// the whole buffer, so typing stays responsive in large files.
+//
+// If the edit inserted or removed a line, every cached token below the
+// cursor is keyed by the wrong line number (the cache is keyed by absolute
+// line index, and lines shifted). A ±1 window around the cursor can't fix
+// that, so any line-count change invalidates the whole cache instead of
+// just widening the dirty window.
+func (e *CustomEditor) NotifyChanged() {
+	lineCount := strings.Count(e.Text, "\n") + 1
+	if lineCount != e.lineCount {
+		e.lineTokens = make(map[int][]Token)
+		e.dirty.Clear()
+		e.lineCount = lineCount
+	} else {
+		start := e.CursorRow - 1
+		if start < 0 {
+			start = 0
+		}
+		e.dirty.MarkRange(start, e.CursorRow+1)
+	}
+	e.rebuildRichText()
+}
+
+func (e *CustomEditor) rebuildRichText() {
+	lines := strings.Split(e.Text, "\n")
+
+	if e.highlighter == nil {
+		e.rich.Segments = []widget.RichTextSegment{plainSegment(e.Text)}
+	} else {
+		for lineNum, line := range lines {
+			if _, cached := e.lineTokens[lineNum]; cached && !e.dirty.IsDirty(lineNum) {
+				continue
+			}
+			e.lineTokens[lineNum] = e.highlighter.Tokenize([]byte(line))
+		}
+		e.dirty.Clear()
+
+		e.rich.Segments = buildSegments(lines, e.lineTokens, e.diagnostics)
+	}
+	e.rich.Refresh()
+
+	e.gutter.Segments = buildGutterSegments(lines, e.diagnostics)
+	e.gutter.Refresh()
+}
+
+// CreateRenderer layers a widget.RichText showing colored tokens underneath
+// the real Entry, whose own text is made transparent so only its caret and
+// selection remain visible. Token colors come from e.theme, looked up by
+// ColorName through a per-instance theme override. A narrow gutter RichText
+// sits to the Entry's left marking which lines have diagnostics; it's one
+// row per logical line, so it only lines up with the editor when a line
+// doesn't wrap - the same approximation every part of this renderer already
+// makes, since there are no real line numbers either.
+func (e *CustomEditor) CreateRenderer() fyne.WidgetRenderer {
+	e.rich = widget.NewRichText()
+	e.rich.Wrapping = fyne.TextWrapWord
+
+	e.gutter = widget.NewRichText()
+	e.gutter.Wrapping = fyne.TextWrapOff
+
+	overlay := container.NewStack(e.rich, &e.Entry)
+	withGutter := container.NewBorder(nil, nil, e.gutter, nil, overlay)
+	themed := container.NewThemeOverride(withGutter, &editorThemeOverride{Theme: theme.DefaultTheme(), syntax: e.theme})
+
+	e.rebuildRichText()
+	return widget.NewSimpleRenderer(themed)
+}
+
+// editorThemeOverride hides the Entry's native text/placeholder color (the
+// RichText overlay draws the visible glyphs instead) and resolves token and
+// diagnostic-severity colors by name for the RichText segments built in
+// buildSegments and buildGutterSegments.
+type editorThemeOverride struct {
+	fyne.Theme
+	syntax *Theme
+}
+
+func (t *editorThemeOverride) Color(name fyne.ThemeColorName, variant fyne.ThemeVariant) color.Color {
+	switch name {
+	case theme.ColorNameForeground, theme.ColorNamePlaceHolder:
+		return color.Transparent
+	case colorNameDiagError:
+		return t.syntax.DiagnosticColor(1)
+	case colorNameDiagWarning:
+		return t.syntax.DiagnosticColor(2)
+	case colorNameDiagInfo:
+		return t.syntax.DiagnosticColor(3)
+	}
+	if c, ok := t.syntax.Colors[TokenType(name)]; ok {
+		return c
+	}
+	return t.Theme.Color(name, variant)
+}
+
+// colorNameDiag{Error,Warning,Info} name the three diagnostic-severity
+// colors editorThemeOverride resolves; diagnosticColorName maps an LSP
+// severity (1=Error, 2=Warning, 3=Information, 4=Hint) onto one of them.
+const (
+	colorNameDiagError   fyne.ThemeColorName = "diagnostic-error"
+	colorNameDiagWarning fyne.ThemeColorName = "diagnostic-warning"
+	colorNameDiagInfo    fyne.ThemeColorName = "diagnostic-info"
+)
+
+func diagnosticColorName(severity int) fyne.ThemeColorName {
+	switch severity {
+	case 1:
+		return colorNameDiagError
+	case 2:
+		return colorNameDiagWarning
+	default:
+		return colorNameDiagInfo
+	}
+}
+
+func plainSegment(text string) *widget.TextSegment {
+	return &widget.TextSegment{Text: text, Style: widget.RichTextStyleInline}
+}
+
+// runeStyle is the per-rune style buildSegments assigns before coalescing
+// runs back into segments: tokenType drives the foreground color and
+// diagSeverity (0 if none) additionally underlines the rune in that
+// severity's color, so diagnostics from the language server show up without
+// losing syntax highlighting everywhere else on the line.
+type runeStyle struct {
+	tokenType    TokenType
+	diagSeverity int
+}
+
+func styledSegment(text string, s runeStyle) *widget.TextSegment {
+	style := widget.RichTextStyle{Inline: true}
+	switch {
+	case s.diagSeverity != 0:
+		style.ColorName = diagnosticColorName(s.diagSeverity)
+		style.TextStyle.Underline = true
+	case s.tokenType != "" && s.tokenType != TokenDefault:
+		style.ColorName = fyne.ThemeColorName(s.tokenType)
+	}
+	return &widget.TextSegment{Text: text, Style: style}
+}
+
+// buildSegments flattens per-line token caches (and any diagnostics
+// overlapping those lines) into the ordered segment list widget.RichText
+// expects. Diagnostic spans take priority over token coloring for the runes
+// they cover, rendered as an underline in the diagnostic's severity color.
+func buildSegments(lines []string, lineTokens map[int][]Token, diags []Diagnostic) []widget.RichTextSegment {
+	var segments []widget.RichTextSegment
+
+	for lineNum, line := range lines {
+		runes := []rune(line)
+		styles := make([]runeStyle, len(runes))
+
+		for _, tok := range lineTokens[lineNum] {
+			end := tok.EndCol
+			if end > len(runes) {
+				end = len(runes)
+			}
+			for col := tok.StartCol; col < end; col++ {
+				styles[col].tokenType = tok.Type
+			}
+		}
+		for _, span := range diagnosticSpansForLine(diags, lineNum, len(runes)) {
+			for col := span.Start; col < span.End; col++ {
+				if styles[col].diagSeverity == 0 || span.Severity < styles[col].diagSeverity {
+					styles[col].diagSeverity = span.Severity
+				}
+			}
+		}
+
+		start := 0
+		for pos := 1; pos <= len(runes); pos++ {
+			if pos < len(runes) && styles[pos] == styles[start] {
+				continue
+			}
+			segments = append(segments, styledSegment(string(runes[start:pos]), styles[start]))
+			start = pos
+		}
+
+		if lineNum < len(lines)-1 {
+			segments = append(segments, plainSegment("\n"))
+		}
+	}
+
+	return segments
+}
+
+// diagSpan is a diagnostic's span clipped to a single line, in rune columns.
+type diagSpan struct {
+	Start, End int
+	Severity   int
+}
+
+// diagnosticSpansForLine clips every diagnostic overlapping line to that
+// line's own column range: lines strictly between a diagnostic's start and
+// end are covered in full, the start line from its Column onward, and the
+// end line up to its EndColumn. A zero-width span (EndColumn == Column, as
+// gopls sends for some diagnostics) is widened to cover at least one rune
+// so it's still visible.
+func diagnosticSpansForLine(diags []Diagnostic, line, lineLen int) []diagSpan {
+	var spans []diagSpan
+	for _, d := range diags {
+		if line < d.Line || line > d.EndLine {
+			continue
+		}
+		start, end := 0, lineLen
+		if line == d.Line {
+			start = d.Column
+		}
+		if line == d.EndLine {
+			end = d.EndColumn
+		}
+		if end > lineLen {
+			end = lineLen
+		}
+		if start >= end {
+			if start >= lineLen {
+				continue
+			}
+			end = start + 1
+		}
+		spans = append(spans, diagSpan{Start: start, End: end, Severity: d.Severity})
+	}
+	return spans
+}
+
+// buildGutterSegments renders one row per source line, marking lines that
+// have a diagnostic with a dot in that diagnostic's severity color (the
+// lowest-numbered, i.e. most severe, if more than one diagnostic covers the
+// line).
+func buildGutterSegments(lines []string, diags []Diagnostic) []widget.RichTextSegment {
+	var segments []widget.RichTextSegment
+	for lineNum := range lines {
+		if severity := lineDiagnosticSeverity(diags, lineNum); severity != 0 {
+			segments = append(segments, &widget.TextSegment{
+				Text:  "●",
+				Style: widget.RichTextStyle{ColorName: diagnosticColorName(severity), Inline: true},
+			})
+		} else {
+			segments = append(segments, plainSegment(" "))
+		}
+		if lineNum < len(lines)-1 {
+			segments = append(segments, plainSegment("\n"))
+		}
+	}
+	return segments
+}
+
+func lineDiagnosticSeverity(diags []Diagnostic, line int) int {
+	severity := 0
+	for _, d := range diags {
+		if line < d.Line || line > d.EndLine {
+			continue
+		}
+		if severity == 0 || d.Severity < severity {
+			severity = d.Severity
+		}
+	}
+	return severity
+}
+
 func NewFocus() *Focus {
 	return &Focus{
-		files:    make(map[string][]string),
-		fontSize: 12,
+		files:         make(map[string][]string),
+		fontSize:      12,
+		autosaveDelay: defaultAutosaveDelay,
 	}
 }
 
-var (
-	goPatterns = map[string]*regexp.Regexp{
-		"#00ADD8": regexp.MustCompile(`\b(func|package|import|return|if|else|for|range|var|type|struct|interface|map|chan|go|defer|select|case|default|break|continue|switch)\b`),
-		"#FFA500": regexp.MustCompile(`"[^"]*"`),
-		"#98C379": regexp.MustCompile(`//.*$`),
+// updateTitle reflects the focused pane's path and dirty state in the
+// window title, replacing the old behavior of using the title itself as
+// the source of truth for "the current file".
+func (f *Focus) updateTitle() {
+	if f.activePane == nil || f.activePane.Buffer == nil {
+		f.window.SetTitle("Focus IDE")
+		return
 	}
 
-	jsonPatterns = map[string]*regexp.Regexp{
-		"#FFA500": regexp.MustCompile(`"[^"]*"`),
-		"#00ADD8": regexp.MustCompile(`\b(true|false|null)\b`),
-		"#98C379": regexp.MustCompile(`[{}\[\],]`),
+	title := f.activePane.Buffer.Path
+	if f.activePane.Buffer.Dirty {
+		title = "● " + title
 	}
+	f.window.SetTitle(title)
+}
 
-	xmlPatterns = map[string]*regexp.Regexp{
-		"#00ADD8": regexp.MustCompile(`<[^>]+>`),
-		"#FFA500": regexp.MustCompile(`"[^"]*"`),
-		"#98C379": regexp.MustCompile(`<!--.*?-->`),
+// showBuffer makes buf visible in the currently focused pane, swapping
+// which file that pane displays rather than creating a new pane.
+func (f *Focus) showBuffer(buf *Buffer) {
+	if f.activePane == nil {
+		return
 	}
-)
+	f.switchPaneBuffer(f.activePane, buf)
+	f.editor = f.activePane.Editor
+	f.tabs.Select(buf.TabItem)
+	f.updateTitle()
+	f.rebuildPaneArea()
+}
 
-func (f *Focus) applySyntaxHighlighting(text string, fileExt string) string {
-	var patterns map[string]*regexp.Regexp
+// switchPaneBuffer points pane at a different buffer, reusing pane's own
+// CustomEditor rather than handing it the new buffer's primary Editor - two
+// panes must never end up sharing one widget instance (see newBufferView).
+// The editor is detached from its old buffer's views and rewired onto the
+// new one, then seeded with the new buffer's text. If the pane's editor is
+// the old buffer's primary Editor, that editor stays with the old buffer
+// (saving, undo and the file watcher read its text) and the pane gets a
+// fresh CustomEditor instead.
+func (f *Focus) switchPaneBuffer(pane *Pane, buf *Buffer) {
+	if pane.Buffer == buf {
+		return
+	}
+	if pane.Buffer != nil {
+		if pane.Editor == pane.Buffer.Editor {
+			pane.Editor = NewCustomEditor()
+		} else {
+			pane.Buffer.views = removeEditor(pane.Buffer.views, pane.Editor)
+		}
+	}
+	pane.Buffer = buf
+	f.wireEditor(buf, pane.Editor)
+	buf.suppressHistory = true
+	pane.Editor.SetText(buf.Editor.Text)
+	buf.suppressHistory = false
+}
 
-	switch fileExt {
-	case ".go":
-		patterns = goPatterns
-	case ".json":
-		patterns = jsonPatterns
-	case ".xml":
-		patterns = xmlPatterns
-	default:
-		return text
+// removeEditor returns views with editor removed, preserving order.
+func removeEditor(views []*CustomEditor, editor *CustomEditor) []*CustomEditor {
+	out := views[:0]
+	for _, v := range views {
+		if v != editor {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func (f *Focus) rebuildPaneArea() {
+	if f.paneArea == nil || f.paneTree == nil {
+		return
+	}
+	f.paneArea.Objects = []fyne.CanvasObject{f.paneTree.CanvasObject(f.focusPane)}
+	f.paneArea.Refresh()
+}
+
+// focusPane marks pane as the active one, e.g. because its editor just
+// gained focus, so per-pane shortcuts (save, undo, format, split) and tab
+// clicks act on the pane the user is actually looking at.
+func (f *Focus) focusPane(pane *Pane) {
+	f.activePane = pane
+	f.editor = pane.Editor
+	f.updateTitle()
+}
+
+// bufferFor returns the open Buffer for path, reading it from disk and
+// registering a tab for it if it isn't open yet.
+func (f *Focus) bufferFor(path string) (*Buffer, error) {
+	for _, buf := range f.buffers {
+		if buf.Path == path {
+			return buf, nil
+		}
+	}
+
+	buf, err := f.newBuffer(path)
+	if err != nil {
+		return nil, err
+	}
+
+	buf.TabItem = container.NewTabItem(filepath.Base(path), widget.NewLabel(""))
+	f.buffers = append(f.buffers, buf)
+	f.tabs.Append(buf.TabItem)
+
+	return buf, nil
+}
+
+// openFile opens path in the focused pane, reusing its Buffer (and tab) if
+// it's already open elsewhere instead of reading it from disk again.
+func (f *Focus) openFile(path string) {
+	buf, err := f.bufferFor(path)
+	if err != nil {
+		dialog.ShowError(err, f.window)
+		return
+	}
+
+	if f.paneTree == nil {
+		f.paneTree = NewPaneTree(buf)
+		f.activePane = f.paneTree.Pane
 	}
 
-	highlighted := text
-	for color, pattern := range patterns {
-		highlighted = pattern.ReplaceAllString(highlighted, fmt.Sprintf(`<span style="color: %s">$0</span>`, color))
+	f.showBuffer(buf)
+}
+
+// restoreLayout tries to reload the previously saved split layout. It
+// returns false if there was nothing to restore, in which case the caller
+// should fall back to opening a single file or directory listing.
+func (f *Focus) restoreLayout() bool {
+	tree, err := f.LoadLayout(f.bufferFor)
+	if err != nil {
+		return false
 	}
 
-	return highlighted
+	f.paneTree = tree
+	leaves := tree.Leaves()
+	if len(leaves) == 0 {
+		return false
+	}
+	f.activePane = leaves[0]
+	f.showBuffer(f.activePane.Buffer)
+	return true
+}
+
+// splitActivePane divides the focused pane in two along the requested
+// axis, showing the same buffer in both halves until the user picks a
+// different tab for the new one. horizontal is Ctrl+\ (side by side);
+// !horizontal is Ctrl+Shift+- (stacked), since Ctrl+- is zoom out.
+func (f *Focus) splitActivePane(horizontal bool) {
+	if f.activePane == nil || f.paneTree == nil {
+		return
+	}
+
+	buf := f.activePane.Buffer
+	newPane := &Pane{Buffer: buf, Editor: f.newBufferView(buf)}
+	if !f.paneTree.SplitAt(f.activePane, horizontal, newPane) {
+		return
+	}
+
+	f.activePane = newPane
+	f.editor = newPane.Editor
+	f.rebuildPaneArea()
 }
 
 func (f *Focus) updateFiles(root string) error {
@@ -112,6 +570,8 @@ func (f *Focus) updateFiles(root string) error {
 	f.files = make(map[string][]string)
 	f.files[f.currentDir] = []string{}
 
+	ignore := loadGitignore(absRoot)
+
 	err = filepath.Walk(absRoot, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -133,6 +593,13 @@ func (f *Focus) updateFiles(root string) error {
 			return nil
 		}
 
+		if rel, relErr := filepath.Rel(absRoot, absPath); relErr == nil && ignore.Matches(rel) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
 		parentDir := filepath.Dir(absPath)
 		f.files[parentDir] = append(f.files[parentDir], absPath)
 
@@ -146,31 +613,29 @@ func (f *Focus) updateFiles(root string) error {
 	return err
 }
 
-func (f *Focus) saveContent() {
-	if f.editor == nil {
+// saveContent persists buf to disk via a write-temp-then-rename, so a
+// crash mid-save never truncates the file. It runs on its own debounce
+// timer (see Buffer.autosave) rather than on every keystroke, and again
+// immediately from the Ctrl+S shortcut. Each Buffer tracks its own path
+// now, so this no longer depends on f.window.Title() (which broke as soon
+// as more than one file could be open at once).
+func (f *Focus) saveContent(buf *Buffer) {
+	content := []byte(buf.Editor.Text)
+	err := atomicWriteFile(buf.Path, content, 0644)
+	if err != nil {
+		dialog.ShowError(err, f.window)
 		return
 	}
 
-	content := f.editor.Text
-	currentFile := f.window.Title()
-	if currentFile != "Focus IDE" {
-		err := os.WriteFile(currentFile, []byte(content), 0644)
-		if err != nil {
-			dialog.ShowError(err, f.window)
-		}
+	buf.lastDiskHash = hashContent(content)
+	buf.Dirty = false
+	if f.activePane != nil && f.activePane.Buffer == buf {
+		f.updateTitle()
 	}
-}
 
-func (f *Focus) loadFile(path string) {
-	content, err := os.ReadFile(path)
-	if err != nil {
-		dialog.ShowError(err, f.window)
-		return
+	if f.lsp != nil {
+		f.lsp.OnSave(buf.Path)
 	}
-
-	f.editor.syntax = filepath.Ext(path)
-	f.editor.SetText(string(content))
-	f.window.SetTitle(path)
 }
 
 func (f *Focus) createUI() {
@@ -196,19 +661,31 @@ func (f *Focus) createUI() {
 				return
 			}
 			if !info.IsDir() {
-				f.loadFile(uid)
+				f.openFile(uid)
 			}
 		},
 	}
 
-	f.editor = NewCustomEditor()
-	f.editor.OnChanged = func(content string) {
-		f.saveContent()
+	f.diagPanel = widget.NewLabel("")
+	f.diagPanel.Wrapping = fyne.TextWrapWord
+
+	f.tabs = container.NewDocTabs()
+	f.tabs.OnSelected = func(item *container.TabItem) {
+		for _, buf := range f.buffers {
+			if buf.TabItem == item {
+				f.showBuffer(buf)
+				return
+			}
+		}
 	}
 
+	f.paneArea = container.NewStack()
+
+	paneAndDiagnostics := container.NewBorder(f.tabs, container.NewScroll(f.diagPanel), nil, nil, f.paneArea)
+
 	split := container.NewHSplit(
 		container.NewScroll(f.tree),
-		container.NewScroll(f.editor),
+		paneAndDiagnostics,
 	)
 	split.SetOffset(0.2)
 
@@ -217,6 +694,15 @@ func (f *Focus) createUI() {
 	// Handle keyboard shortcuts
 	zoomInShortcut := &desktop.CustomShortcut{KeyName: fyne.KeyEqual, Modifier: desktop.ControlModifier}
 	zoomOutShortcut := &desktop.CustomShortcut{KeyName: fyne.KeyMinus, Modifier: desktop.ControlModifier}
+	// Ctrl+Shift+F is claimed by project search below, so formatting moves
+	// to the same chord editors like VS Code use for it.
+	formatShortcut := &desktop.CustomShortcut{KeyName: fyne.KeyF, Modifier: desktop.ShiftModifier | desktop.AltModifier}
+	vsplitShortcut := &desktop.CustomShortcut{KeyName: fyne.KeyBackslash, Modifier: desktop.ControlModifier}
+	hsplitShortcut := &desktop.CustomShortcut{KeyName: fyne.KeyMinus, Modifier: desktop.ControlModifier | desktop.ShiftModifier}
+	saveShortcut := &desktop.CustomShortcut{KeyName: fyne.KeyS, Modifier: desktop.ControlModifier}
+	undoShortcut := &desktop.CustomShortcut{KeyName: fyne.KeyZ, Modifier: desktop.ControlModifier}
+	findFileShortcut := &desktop.CustomShortcut{KeyName: fyne.KeyP, Modifier: desktop.ControlModifier}
+	findInProjectShortcut := &desktop.CustomShortcut{KeyName: fyne.KeyF, Modifier: desktop.ControlModifier | desktop.ShiftModifier}
 
 	f.window.Canvas().AddShortcut(zoomInShortcut, func(shortcut fyne.Shortcut) {
 		f.editor.SetFontSize(f.editor.fontSize + 1)
@@ -227,10 +713,65 @@ func (f *Focus) createUI() {
 			f.editor.SetFontSize(f.editor.fontSize - 1)
 		}
 	})
+
+	f.window.Canvas().AddShortcut(formatShortcut, func(shortcut fyne.Shortcut) {
+		if f.lsp != nil && f.activePane != nil {
+			f.lsp.Format(f.activePane.Buffer.Path)
+		}
+	})
+
+	f.window.Canvas().AddShortcut(vsplitShortcut, func(shortcut fyne.Shortcut) {
+		f.splitActivePane(true)
+	})
+
+	f.window.Canvas().AddShortcut(hsplitShortcut, func(shortcut fyne.Shortcut) {
+		f.splitActivePane(false)
+	})
+
+	f.window.Canvas().AddShortcut(saveShortcut, func(shortcut fyne.Shortcut) {
+		if f.activePane != nil {
+			f.activePane.Buffer.autosave.Flush()
+		}
+	})
+
+	f.window.Canvas().AddShortcut(undoShortcut, func(shortcut fyne.Shortcut) {
+		if f.activePane != nil {
+			f.activePane.Buffer.Undo()
+		}
+	})
+
+	f.window.Canvas().AddShortcut(findFileShortcut, func(shortcut fyne.Shortcut) {
+		f.ShowFileFinder()
+	})
+
+	f.window.Canvas().AddShortcut(findInProjectShortcut, func(shortcut fyne.Shortcut) {
+		f.ShowProjectSearch()
+	})
+
+	f.window.SetCloseIntercept(func() {
+		for _, buf := range f.buffers {
+			buf.autosave.Flush()
+			buf.undoDebounce.Flush()
+		}
+		if f.lsp != nil {
+			f.lsp.Shutdown()
+		}
+		if f.watcher != nil {
+			f.watcher.Close()
+		}
+		if err := f.SaveLayout(); err != nil {
+			log.Println("save layout:", err)
+		}
+		f.window.Close()
+	})
 }
 
 func main() {
+	loadSyntaxDir("syntax")
+	loadThemeFile(themeConfigPath())
+
 	focus := NewFocus()
+	focus.lsp = newLSPManager(focus)
 	a := app.New()
 	focus.window = a.NewWindow("Focus IDE")
 	focus.window.Resize(fyne.NewSize(800, 600))
@@ -263,12 +804,22 @@ func main() {
 	focus.tree.Root = absPath
 	focus.tree.Refresh()
 
+	if watcher, err := newFileWatcher(focus, absPath); err != nil {
+		log.Println("file watcher:", err)
+	} else {
+		focus.watcher = watcher
+		watcher.Start()
+	}
+
 	if !fileInfo.IsDir() {
 		absFilePath, err := filepath.Abs(path)
 		if err != nil {
 			log.Fatal(err)
 		}
-		focus.loadFile(absFilePath)
+		focus.openFile(absFilePath)
+	} else if !focus.restoreLayout() {
+		// no saved layout and no file given on the command line; start
+		// with just the directory tree until the user picks a file.
 	}
 
 	focus.window.ShowAndRun()
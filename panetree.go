@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+)
+
+// Pane is one visible slot in the split layout. It shows a single Buffer at
+// a time; switching tabs while a pane is focused swaps which Buffer it
+// points at instead of recreating the pane.
+type Pane struct {
+	Buffer *Buffer
+	Editor *CustomEditor
+}
+
+// paneSplit is an internal node of the PaneTree: a horizontal (Ctrl+\) or
+// vertical (Ctrl+Shift+-) divider between two child trees. live points at the
+// on-screen *container.Split once rendered, so its current (possibly
+// user-dragged) Offset can be read back when the layout is saved.
+type paneSplit struct {
+	Horizontal bool
+	Offset     float64
+	A, B       *PaneTree
+	live       *container.Split
+}
+
+// PaneTree is a recursive binary split of editor panes. A leaf has Pane set
+// and Split nil; an internal node has Split set and Pane nil.
+type PaneTree struct {
+	Pane  *Pane
+	Split *paneSplit
+}
+
+func NewPaneTree(buf *Buffer) *PaneTree {
+	return &PaneTree{Pane: &Pane{Buffer: buf, Editor: buf.Editor}}
+}
+
+// SplitAt finds the leaf holding target and turns it into a split between
+// the existing pane and newPane, reporting whether target was found. newPane
+// must already carry its own CustomEditor (see Focus.newBufferView) - two
+// panes showing the same buffer must never share a widget instance, or
+// focus-tracking and rendering for one clobbers the other.
+func (t *PaneTree) SplitAt(target *Pane, horizontal bool, newPane *Pane) bool {
+	if t.Pane == target {
+		original := t.Pane
+
+		t.Pane = nil
+		t.Split = &paneSplit{
+			Horizontal: horizontal,
+			Offset:     0.5,
+			A:          &PaneTree{Pane: original},
+			B:          &PaneTree{Pane: newPane},
+		}
+		return true
+	}
+
+	if t.Split == nil {
+		return false
+	}
+	return t.Split.A.SplitAt(target, horizontal, newPane) || t.Split.B.SplitAt(target, horizontal, newPane)
+}
+
+// CanvasObject renders the tree: a leaf is just its Buffer's editor in a
+// scroller, an internal node is a draggable Split recursing into both
+// children. The live *container.Split is kept on paneSplit so its current
+// offset (which changes as the user drags the separator) can be read back
+// later for persistence.
+//
+// onFocus is wired to every leaf's editor so that clicking into a pane (or
+// tabbing into it) updates which pane is "active", instead of only the
+// pane most recently created by a split ever receiving focus-gated actions
+// like Ctrl+S, Ctrl+Z, and format.
+func (t *PaneTree) CanvasObject(onFocus func(*Pane)) fyne.CanvasObject {
+	if t.Pane != nil {
+		t.Pane.Editor.onFocusGained = func() { onFocus(t.Pane) }
+		return container.NewScroll(t.Pane.Editor)
+	}
+
+	var split *container.Split
+	if t.Split.Horizontal {
+		split = container.NewHSplit(t.Split.A.CanvasObject(onFocus), t.Split.B.CanvasObject(onFocus))
+	} else {
+		split = container.NewVSplit(t.Split.A.CanvasObject(onFocus), t.Split.B.CanvasObject(onFocus))
+	}
+	split.SetOffset(t.Split.Offset)
+	t.Split.live = split
+	return split
+}
+
+// syncOffset copies the live, possibly user-dragged split offset back onto
+// the tree so it can be serialized by SaveLayout.
+func (t *PaneTree) syncOffset() {
+	if t.Split == nil {
+		return
+	}
+	if t.Split.live != nil {
+		t.Split.Offset = t.Split.live.Offset
+	}
+	t.Split.A.syncOffset()
+	t.Split.B.syncOffset()
+}
+
+// Leaves returns every Pane in the tree, depth-first.
+func (t *PaneTree) Leaves() []*Pane {
+	if t.Pane != nil {
+		return []*Pane{t.Pane}
+	}
+	if t.Split == nil {
+		return nil
+	}
+	return append(t.Split.A.Leaves(), t.Split.B.Leaves()...)
+}
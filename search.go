@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// searchResult is one matching line from a project search, with enough
+// context to jump straight to it in the editor.
+type searchResult struct {
+	Path string
+	Line int // 0-based, matches CustomEditor.Entry.CursorRow
+	Text string
+}
+
+// searchProject walks root with a pool of runtime.NumCPU() worker
+// goroutines consuming a path channel, skipping the same dotfiles and
+// node_modules directories updateFiles does, plus anything root's
+// .gitignore excludes, and calls onMatch for every line containing query.
+// onMatch may be called concurrently from multiple workers.
+func searchProject(root, query string, onMatch func(searchResult)) {
+	if query == "" {
+		return
+	}
+
+	ignore := loadGitignore(root)
+	paths := make(chan string, 64)
+
+	var workers sync.WaitGroup
+	for i := 0; i < runtime.NumCPU(); i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for path := range paths {
+				searchFile(path, query, onMatch)
+			}
+		}()
+	}
+
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if path == root {
+			return nil
+		}
+		if strings.HasPrefix(info.Name(), ".") || info.Name() == "node_modules" {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if rel, relErr := filepath.Rel(root, path); relErr == nil && ignore.Matches(rel) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.IsDir() {
+			paths <- path
+		}
+		return nil
+	})
+	close(paths)
+	workers.Wait()
+}
+
+// searchFile scans path line by line for query, reporting each match
+// through onMatch. Unreadable files are skipped rather than treated as an
+// error, since the walk is best-effort across a whole tree.
+func searchFile(path, query string, onMatch func(searchResult)) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		text := scanner.Text()
+		if strings.Contains(text, query) {
+			onMatch(searchResult{Path: path, Line: line, Text: strings.TrimSpace(text)})
+		}
+		line++
+	}
+}
+
+// ShowProjectSearch opens the Ctrl+Shift+F panel: a query Entry that kicks
+// off searchProject on submit, streaming matches into a List whose entries
+// jump to the file and line via openFile plus cursor positioning.
+func (f *Focus) ShowProjectSearch() {
+	var (
+		mu      sync.Mutex
+		results []searchResult
+	)
+
+	list := widget.NewList(
+		func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(results)
+		},
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			mu.Lock()
+			defer mu.Unlock()
+			if id >= len(results) {
+				return
+			}
+			r := results[id]
+			obj.(*widget.Label).SetText(fmt.Sprintf("%s:%d: %s", displayPath(f.currentDir, r.Path), r.Line+1, r.Text))
+		},
+	)
+
+	var popup *widget.PopUp
+	list.OnSelected = func(id widget.ListItemID) {
+		mu.Lock()
+		if id >= len(results) {
+			mu.Unlock()
+			return
+		}
+		r := results[id]
+		mu.Unlock()
+
+		if popup != nil {
+			popup.Hide()
+		}
+		f.openFile(r.Path)
+		if f.activePane != nil {
+			editor := f.activePane.Editor
+			editor.CursorRow = r.Line
+			editor.CursorColumn = 0
+			editor.Refresh()
+		}
+	}
+
+	query := widget.NewEntry()
+	query.SetPlaceHolder("Search project…")
+	query.OnSubmitted = func(text string) {
+		mu.Lock()
+		results = nil
+		mu.Unlock()
+		list.Refresh()
+
+		go searchProject(f.currentDir, text, func(r searchResult) {
+			mu.Lock()
+			results = append(results, r)
+			mu.Unlock()
+			fyne.Do(func() { list.Refresh() })
+		})
+	}
+
+	content := container.NewBorder(query, nil, nil, nil, list)
+
+	popup = widget.NewPopUp(content, f.window.Canvas())
+	popup.Resize(fyne.NewSize(640, 420))
+	popup.Show()
+	f.window.Canvas().Focus(query)
+}
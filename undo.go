@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// undoEntry is one recorded edit. The very first entry in a log carries a
+// full Snapshot (there's no previous entry to diff against); every entry
+// after that carries only the Hunks that actually changed, computed from
+// the same myersDiff the conflict diff view uses but stripped of its
+// diffEqual runs - so an edit to one line of a long file costs one small
+// hunk, not a line-for-line copy of the whole file.
+type undoEntry struct {
+	Timestamp time.Time  `json:"timestamp"`
+	Snapshot  []string   `json:"snapshot,omitempty"`
+	Hunks     []undoHunk `json:"hunks,omitempty"`
+}
+
+// undoHunk is one contiguous changed region: Insert replaces the Delete
+// lines starting at Start (an index into the previous entry's reconstructed
+// line slice).
+type undoHunk struct {
+	Start  int      `json:"start"`
+	Delete int      `json:"delete"`
+	Insert []string `json:"insert"`
+}
+
+// hunksFromDiff collapses a myersDiff edit script down to just its changed
+// runs, dropping the diffEqual lines in between (and the positions where
+// nothing changed at all).
+func hunksFromDiff(ops []diffLine) []undoHunk {
+	var hunks []undoHunk
+	prevIndex := 0
+
+	for i := 0; i < len(ops); {
+		if ops[i].Op == diffEqual {
+			prevIndex++
+			i++
+			continue
+		}
+
+		start := prevIndex
+		var deleted int
+		var inserted []string
+		for i < len(ops) && ops[i].Op != diffEqual {
+			switch ops[i].Op {
+			case diffDelete:
+				deleted++
+				prevIndex++
+			case diffInsert:
+				inserted = append(inserted, ops[i].Text)
+			}
+			i++
+		}
+		hunks = append(hunks, undoHunk{Start: start, Delete: deleted, Insert: inserted})
+	}
+
+	return hunks
+}
+
+// applyHunks splices hunks into lines (the previous entry's reconstructed
+// text) to produce the next entry's full line slice. Each hunk's Start is
+// an index into lines as it stood before any of this entry's hunks were
+// applied, which is exactly how hunksFromDiff computed them.
+func applyHunks(lines []string, hunks []undoHunk) []string {
+	out := make([]string, 0, len(lines))
+	pos := 0
+	for _, h := range hunks {
+		out = append(out, lines[pos:h.Start]...)
+		out = append(out, h.Insert...)
+		pos = h.Start + h.Delete
+	}
+	out = append(out, lines[pos:]...)
+	return out
+}
+
+// undoLog is the on-disk journal of a single file's edit history, stored
+// under ~/.local/share/focus/undo/<sha256(path)>.log so paths with slashes
+// don't need escaping into a filename. hasEntries tracks whether the log
+// already has a first (snapshot) entry, so Append knows whether the next
+// one it writes needs to be a snapshot too or can be a hunk-only delta.
+type undoLog struct {
+	path       string
+	hasEntries bool
+}
+
+func undoLogPathFor(filePath string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(filePath))
+	name := hex.EncodeToString(sum[:]) + ".log"
+	return filepath.Join(home, ".local", "share", "focus", "undo", name), nil
+}
+
+func newUndoLog(filePath string) (*undoLog, error) {
+	path, err := undoLogPathFor(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	info, statErr := os.Stat(path)
+	return &undoLog{path: path, hasEntries: statErr == nil && info.Size() > 0}, nil
+}
+
+// Append records prevText -> text as the buffer's latest edit: a full
+// Snapshot if this is the log's first entry, otherwise just the Hunks that
+// changed.
+func (u *undoLog) Append(prevText, text string) error {
+	f, err := os.OpenFile(u.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	entry := undoEntry{Timestamp: time.Now()}
+	if u.hasEntries {
+		ops := myersDiff(strings.Split(prevText, "\n"), strings.Split(text, "\n"))
+		entry.Hunks = hunksFromDiff(ops)
+	} else {
+		entry.Snapshot = strings.Split(text, "\n")
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	u.hasEntries = true
+	return nil
+}
+
+// Entries reads the whole journal back in order, replaying each entry's
+// hunks on top of the last reconstructed version (starting from the first
+// entry's Snapshot) to recover the full text at every recorded edit. A
+// missing log (the common case for a file that's never been edited before)
+// is not an error.
+func (u *undoLog) Entries() ([]string, error) {
+	f, err := os.Open(u.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var texts []string
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry undoEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if entry.Snapshot != nil {
+			lines = entry.Snapshot
+		} else {
+			lines = applyHunks(lines, entry.Hunks)
+		}
+		texts = append(texts, strings.Join(lines, "\n"))
+	}
+	return texts, scanner.Err()
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// fileWatcher keeps f.files (and any open buffer) in sync with changes
+// made outside the editor. Without it, updateFiles's one-time walk goes
+// stale the moment a file is created, renamed, or deleted on disk, and an
+// externally modified file would be silently clobbered by the next
+// autosave.
+type fileWatcher struct {
+	focus   *Focus
+	watcher *fsnotify.Watcher
+}
+
+// newFileWatcher watches every directory already known in f.files (root
+// plus everything updateFiles walked) and keeps watching new directories
+// as they're created, since fsnotify isn't recursive on its own.
+func newFileWatcher(f *Focus, root string) (*fileWatcher, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	fw := &fileWatcher{focus: f, watcher: w}
+	if err := w.Add(root); err != nil {
+		w.Close()
+		return nil, err
+	}
+	for dir := range f.files {
+		if err := w.Add(dir); err != nil {
+			log.Println("watch:", err)
+		}
+	}
+
+	return fw, nil
+}
+
+// Start consumes fsnotify events on its own goroutine until Close is
+// called.
+func (fw *fileWatcher) Start() {
+	go func() {
+		for {
+			select {
+			case event, ok := <-fw.watcher.Events:
+				if !ok {
+					return
+				}
+				fyne.Do(func() { fw.handleEvent(event) })
+			case err, ok := <-fw.watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Println("watch error:", err)
+			}
+		}
+	}()
+}
+
+func (fw *fileWatcher) Close() error {
+	return fw.watcher.Close()
+}
+
+func (fw *fileWatcher) handleEvent(event fsnotify.Event) {
+	if event.Op&(fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0 {
+		fw.refreshTree(event)
+	}
+	if event.Op&(fsnotify.Write|fsnotify.Rename) != 0 {
+		fw.checkExternalChange(event.Name)
+	}
+}
+
+// refreshTree patches f.files for a create/remove/rename event and asks
+// the tree to redraw, instead of re-walking the whole project on every
+// change.
+func (fw *fileWatcher) refreshTree(event fsnotify.Event) {
+	f := fw.focus
+	parent := filepath.Dir(event.Name)
+
+	switch {
+	case event.Op&fsnotify.Create != 0:
+		if !containsPath(f.files[parent], event.Name) {
+			f.files[parent] = append(f.files[parent], event.Name)
+		}
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			if _, ok := f.files[event.Name]; !ok {
+				f.files[event.Name] = []string{}
+			}
+			if err := fw.watcher.Add(event.Name); err != nil {
+				log.Println("watch:", err)
+			}
+		}
+
+	case event.Op&(fsnotify.Remove|fsnotify.Rename) != 0:
+		f.files[parent] = removePath(f.files[parent], event.Name)
+		if _, ok := f.files[event.Name]; ok {
+			delete(f.files, event.Name)
+			fw.watcher.Remove(event.Name)
+		}
+	}
+
+	f.tree.Refresh()
+}
+
+func containsPath(paths []string, path string) bool {
+	for _, p := range paths {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+func removePath(paths []string, path string) []string {
+	out := paths[:0]
+	for _, p := range paths {
+		if p != path {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// checkExternalChange compares path's on-disk content against what was
+// last seen for it, and prompts for how to resolve the conflict if the
+// buffer that has it open disagrees with both.
+func (fw *fileWatcher) checkExternalChange(path string) {
+	f := fw.focus
+
+	var buf *Buffer
+	for _, b := range f.buffers {
+		if b.Path == path {
+			buf = b
+			break
+		}
+	}
+	if buf == nil {
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	newHash := hashContent(data)
+	if newHash == buf.lastDiskHash {
+		return // our own autosave triggered this event
+	}
+	buf.lastDiskHash = newHash
+
+	if string(data) == buf.Editor.Text {
+		return // external edit happened to match what's already in the buffer
+	}
+
+	f.showConflictPrompt(buf, string(data))
+}
+
+func hashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// showConflictPrompt offers the three ways to resolve an external edit to
+// a file that's currently open: reload it, keep the in-memory version (it
+// will overwrite the disk version on the next autosave), or look at a
+// side-by-side diff before deciding.
+func (f *Focus) showConflictPrompt(buf *Buffer, externalContent string) {
+	var d dialog.Dialog
+
+	reload := widget.NewButton("Reload from disk", func() {
+		buf.Editor.SetText(externalContent)
+		buf.Dirty = false
+		d.Hide()
+	})
+	keep := widget.NewButton("Keep my version", func() {
+		d.Hide()
+	})
+	viewDiff := widget.NewButton("View diff…", func() {
+		d.Hide()
+		f.showDiffView(buf, externalContent)
+	})
+
+	content := container.NewVBox(
+		widget.NewLabel(fmt.Sprintf("%s changed on disk since it was opened.", filepath.Base(buf.Path))),
+		reload,
+		keep,
+		viewDiff,
+	)
+
+	d = dialog.NewCustom("External change detected", "Cancel", content, f.window)
+	d.Show()
+}
+
+// showDiffView renders externalContent (the on-disk version) and buf's
+// current text side by side, aligned by myersDiff, with deleted lines
+// highlighted on the left and inserted lines on the right. The bottom
+// buttons apply the same two whole-file resolutions as the conflict
+// prompt, since the buffer has only one CustomEditor to merge into.
+func (f *Focus) showDiffView(buf *Buffer, externalContent string) {
+	disk := strings.Split(externalContent, "\n")
+	ours := strings.Split(buf.Editor.Text, "\n")
+	ops := myersDiff(disk, ours)
+
+	left := widget.NewRichText()
+	right := widget.NewRichText()
+	for _, op := range ops {
+		switch op.Op {
+		case diffEqual:
+			left.Segments = append(left.Segments, diffLineSegment(op.Text, ""))
+			right.Segments = append(right.Segments, diffLineSegment(op.Text, ""))
+		case diffDelete:
+			left.Segments = append(left.Segments, diffLineSegment(op.Text, theme.ColorNameError))
+		case diffInsert:
+			right.Segments = append(right.Segments, diffLineSegment(op.Text, theme.ColorNameSuccess))
+		}
+	}
+
+	columns := container.NewHSplit(
+		container.NewScroll(left),
+		container.NewScroll(right),
+	)
+	columns.SetOffset(0.5)
+
+	var d dialog.Dialog
+	reload := widget.NewButton("Use disk version", func() {
+		buf.Editor.SetText(externalContent)
+		buf.Dirty = false
+		d.Hide()
+	})
+	keep := widget.NewButton("Keep my version", func() {
+		d.Hide()
+	})
+
+	content := container.NewBorder(
+		container.NewHBox(widget.NewLabel("On disk"), widget.NewLabel("    In editor")),
+		container.NewHBox(reload, keep),
+		nil, nil,
+		columns,
+	)
+
+	d = dialog.NewCustom(fmt.Sprintf("Diff: %s", filepath.Base(buf.Path)), "Close", content, f.window)
+	d.Resize(fyne.NewSize(900, 600))
+	d.Show()
+}
+
+func diffLineSegment(text string, colorName fyne.ThemeColorName) *widget.TextSegment {
+	style := widget.RichTextStyleInline
+	if colorName != "" {
+		style = widget.RichTextStyle{ColorName: colorName, Inline: true}
+	}
+	return &widget.TextSegment{Text: text + "\n", Style: style}
+}